@@ -0,0 +1,33 @@
+package config
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+)
+
+// EncryptionConfig holds the key used to encrypt sensitive values (such as
+// linked-identity OAuth tokens) before they are persisted.
+type EncryptionConfig struct {
+	key []byte
+}
+
+// NewEncryptionConfig builds an EncryptionConfig from the ENCRYPTION_KEY
+// environment variable, which must be a base64-encoded 32-byte AES-256
+// key.
+func NewEncryptionConfig() (*EncryptionConfig, error) {
+	encoded := os.Getenv("ENCRYPTION_KEY")
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("config: invalid ENCRYPTION_KEY: %w", err)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("config: ENCRYPTION_KEY must decode to 32 bytes, got %d", len(key))
+	}
+	return &EncryptionConfig{key: key}, nil
+}
+
+// Key returns the raw AES-256 key.
+func (c *EncryptionConfig) Key() []byte {
+	return c.key
+}
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"os"
+	"strings"
+)
+
+// GithubOAuthConfig holds the environment-driven settings for the Github
+// OAuth provider.
+type GithubOAuthConfig struct {
+	clientId     string
+	clientSecret string
+	redirectURL  string
+	frontendURL  string
+	allowedOrgs  []string
+	allowedTeams []string
+	apiBaseURL   string
+}
+
+// NewGithubOAuthConfig builds a GithubOAuthConfig from the process
+// environment.
+func NewGithubOAuthConfig() *GithubOAuthConfig {
+	return &GithubOAuthConfig{
+		clientId:     os.Getenv("GITHUB_OAUTH_CLIENT_ID"),
+		clientSecret: os.Getenv("GITHUB_OAUTH_CLIENT_SECRET"),
+		redirectURL:  os.Getenv("GITHUB_OAUTH_REDIRECT_URL"),
+		frontendURL:  os.Getenv("GITHUB_OAUTH_FRONTEND_URL"),
+		allowedOrgs:  splitAndTrim(os.Getenv("GITHUB_OAUTH_ALLOWED_ORGS")),
+		allowedTeams: splitAndTrim(os.Getenv("GITHUB_OAUTH_ALLOWED_TEAMS")),
+		// apiBaseURL is normally left empty, which makes the Github API
+		// client use its real https://api.github.com/ default. Tests
+		// point it at a authtest.FakeIdP instead.
+		apiBaseURL: os.Getenv("GITHUB_API_BASE_URL"),
+	}
+}
+
+func (c *GithubOAuthConfig) ClientId() string {
+	return c.clientId
+}
+
+func (c *GithubOAuthConfig) ClientSecret() string {
+	return c.clientSecret
+}
+
+func (c *GithubOAuthConfig) RedirectURL() string {
+	return c.redirectURL
+}
+
+func (c *GithubOAuthConfig) FrontendURL() string {
+	return c.frontendURL
+}
+
+// AllowedOrganisations returns the configured allow-list of Github
+// organisation logins. A user who belongs to any of them, or to any team
+// in AllowedTeams, may log in. When both lists are empty, membership is
+// not checked.
+func (c *GithubOAuthConfig) AllowedOrganisations() []string {
+	return c.allowedOrgs
+}
+
+// AllowedTeams returns the configured allow-list of "org/team-slug" pairs.
+// It is checked only when the user matched no entry of
+// AllowedOrganisations; membership of any listed team then admits the
+// user. An empty list means team membership is not checked.
+func (c *GithubOAuthConfig) AllowedTeams() []string {
+	return c.allowedTeams
+}
+
+// APIBaseURL overrides the Github REST API base URL used for the
+// org/team membership check. Empty means use the real API.
+func (c *GithubOAuthConfig) APIBaseURL() string {
+	return c.apiBaseURL
+}
+
+func splitAndTrim(value string) []string {
+	if value == "" {
+		return nil
+	}
+	var result []string
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part != "" {
+			result = append(result, part)
+		}
+	}
+	return result
+}
@@ -0,0 +1,104 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// JWTConfig holds the environment-driven settings used to sign and verify
+// session JWTs. Only HS256 is supported today; the signing method is kept
+// behind SigningMethod/SigningKey/VerificationKey so RS256 can be added
+// later without touching TokenService.
+type JWTConfig struct {
+	secret          []byte
+	accessTokenTTL  time.Duration
+	refreshTokenTTL time.Duration
+	secureCookies   bool
+}
+
+// NewJWTConfig builds a JWTConfig from the process environment.
+//
+//   - JWT_SIGNING_KEY: required, the HMAC secret used to sign tokens.
+//   - JWT_ACCESS_TOKEN_TTL_MINUTES: optional, defaults to 15.
+//   - JWT_REFRESH_TOKEN_TTL_HOURS: optional, defaults to 720 (30 days).
+//   - SESSION_COOKIE_SECURE: optional, defaults to true. Set to "false"
+//     only for plain-HTTP local development; session cookies must be
+//     Secure everywhere else.
+func NewJWTConfig() (*JWTConfig, error) {
+	secret := os.Getenv("JWT_SIGNING_KEY")
+	if secret == "" {
+		return nil, fmt.Errorf("config: JWT_SIGNING_KEY is required")
+	}
+
+	accessTTL := 15 * time.Minute
+	if raw := os.Getenv("JWT_ACCESS_TOKEN_TTL_MINUTES"); raw != "" {
+		minutes, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, fmt.Errorf("config: invalid JWT_ACCESS_TOKEN_TTL_MINUTES: %w", err)
+		}
+		accessTTL = time.Duration(minutes) * time.Minute
+	}
+
+	refreshTTL := 720 * time.Hour
+	if raw := os.Getenv("JWT_REFRESH_TOKEN_TTL_HOURS"); raw != "" {
+		hours, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, fmt.Errorf("config: invalid JWT_REFRESH_TOKEN_TTL_HOURS: %w", err)
+		}
+		refreshTTL = time.Duration(hours) * time.Hour
+	}
+
+	secureCookies := true
+	if raw := os.Getenv("SESSION_COOKIE_SECURE"); raw != "" {
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("config: invalid SESSION_COOKIE_SECURE: %w", err)
+		}
+		secureCookies = parsed
+	}
+
+	return &JWTConfig{
+		secret:          []byte(secret),
+		accessTokenTTL:  accessTTL,
+		refreshTokenTTL: refreshTTL,
+		secureCookies:   secureCookies,
+	}, nil
+}
+
+// SigningMethod is the JWT signing algorithm in use.
+func (c *JWTConfig) SigningMethod() jwt.SigningMethod {
+	return jwt.SigningMethodHS256
+}
+
+// SigningKey returns the key used to sign new tokens.
+func (c *JWTConfig) SigningKey() interface{} {
+	return c.secret
+}
+
+// VerificationKey returns the key used to verify an incoming token's
+// signature.
+func (c *JWTConfig) VerificationKey() interface{} {
+	return c.secret
+}
+
+// AccessTokenTTL is how long a freshly issued access token stays valid.
+func (c *JWTConfig) AccessTokenTTL() time.Duration {
+	return c.accessTokenTTL
+}
+
+// RefreshTokenTTL is how long a session may be refreshed before the user
+// must fully re-authenticate.
+func (c *JWTConfig) RefreshTokenTTL() time.Duration {
+	return c.refreshTokenTTL
+}
+
+// SecureCookies reports whether the session cookie should be marked
+// Secure (HTTPS-only). True unless explicitly disabled for local
+// development.
+func (c *JWTConfig) SecureCookies() bool {
+	return c.secureCookies
+}
@@ -0,0 +1,69 @@
+package middlewares
+
+import (
+	"ai-developer/app/services"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ContextKeyUser is the gin context key AuthRequired stores the resolved
+// user under.
+const ContextKeyUser = "auth.user"
+
+// sessionCookieName must match auth.SessionCookieName. It is duplicated
+// here, rather than imported, so this package doesn't import
+// ai-developer/app/services/auth, which itself imports middlewares
+// (for ContextKeyUser) — importing it back would be a cycle.
+const sessionCookieName = "ss_session"
+
+// AuthRequired validates the caller's session JWT and injects the
+// resolved user into the gin context, rejecting the request with 401 if
+// no valid, unrevoked session is found. It accepts the session either
+// from the ss_session cookie (browser clients) or an "Authorization:
+// Bearer <token>" header (CLI/API clients).
+func AuthRequired(tokenService *services.TokenService, userService *services.UserService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenString := bearerToken(c)
+		if tokenString == "" {
+			var err error
+			tokenString, err = c.Cookie(sessionCookieName)
+			if err != nil || tokenString == "" {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing session"})
+				return
+			}
+		}
+
+		claims, err := tokenService.Parse(tokenString)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid session"})
+			return
+		}
+
+		userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid session"})
+			return
+		}
+
+		user, err := userService.GetUserById(uint(userID))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid session"})
+			return
+		}
+
+		c.Set(ContextKeyUser, user)
+		c.Next()
+	}
+}
+
+func bearerToken(c *gin.Context) string {
+	header := c.GetHeader("Authorization")
+	const prefix = "Bearer "
+	if !strings.HasPrefix(header, prefix) {
+		return ""
+	}
+	return strings.TrimPrefix(header, prefix)
+}
@@ -0,0 +1,16 @@
+package models
+
+import "gorm.io/gorm"
+
+// Migrate creates or updates the tables backing the Github OAuth work:
+// the organisations.github_org column, session persistence, and linked
+// external identities. Call it once during application startup, after
+// the database connection is established, alongside any other
+// AutoMigrate calls for the rest of the schema.
+func Migrate(db *gorm.DB) error {
+	return db.AutoMigrate(
+		&Organisation{},
+		&Session{},
+		&UserIdentity{},
+	)
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// Organisation is a SuperCoder workspace. Each User belongs to exactly
+// one Organisation via User.OrganisationID.
+type Organisation struct {
+	ID   uint   `json:"id" gorm:"primaryKey"`
+	Name string `json:"name" gorm:"size:191;not null"`
+	// GithubOrg is the Github organisation login this Organisation is
+	// shared with, set by OrganisationService.GetOrCreateOrganisationByGithubOrg
+	// when a user logs in via an allow-listed Github org. Empty for
+	// organisations created outside that flow.
+	GithubOrg string    `json:"github_org" gorm:"size:191;index"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// Session records a single issued JWT so it can be revoked (logout) or
+// rejected after expiry server-side, even though the JWT itself is
+// self-contained.
+type Session struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	UserID    uint       `json:"user_id" gorm:"not null;index"`
+	JTI       string     `json:"jti" gorm:"size:64;not null;uniqueIndex"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// UserIdentity links a SuperCoder user to a single external identity
+// provider account. It is keyed by the provider's own stable user id
+// (e.g. Github's numeric ID) rather than email, since a provider account's
+// email can change after the identity was first linked, and different
+// providers can otherwise report the same email for unrelated accounts.
+type UserIdentity struct {
+	ID                    uint       `json:"id" gorm:"primaryKey"`
+	Provider              string     `json:"provider" gorm:"size:64;not null;uniqueIndex:idx_user_identities_provider_user"`
+	ProviderUserID        string     `json:"provider_user_id" gorm:"size:191;not null;uniqueIndex:idx_user_identities_provider_user"`
+	UserID                uint       `json:"user_id" gorm:"not null;index"`
+	AccessTokenEncrypted  string     `json:"-" gorm:"type:text"`
+	RefreshTokenEncrypted string     `json:"-" gorm:"type:text"`
+	ExpiresAt             *time.Time `json:"expires_at"`
+	CreatedAt             time.Time  `json:"created_at"`
+	UpdatedAt             time.Time  `json:"updated_at"`
+}
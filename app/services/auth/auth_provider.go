@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuthProvider is implemented by every external identity provider (GitHub,
+// Google, GitLab, Bitbucket, generic OIDC, ...). The OAuth flow is
+// intentionally split into two steps so that the login-initiation request
+// and the provider's callback request are never conflated: BeginLogin only
+// ever redirects the browser to the provider, and HandleCallback only ever
+// runs once the provider redirects back with a code and state.
+type AuthProvider interface {
+	// BeginLogin generates CSRF state, persists it, and redirects the
+	// browser to the provider's consent screen.
+	BeginLogin(c *gin.Context) error
+	// HandleCallback validates the CSRF state, exchanges the code for a
+	// token, and resolves (or creates) the authenticated user.
+	HandleCallback(c *gin.Context) (user interface{}, err error)
+}
+
+// Registry maps a provider name (e.g. "github", "google") to the
+// AuthProvider implementation handling that provider. It is safe for
+// concurrent use so it can be populated once at startup and read from
+// request handlers afterwards.
+type Registry struct {
+	mu        sync.RWMutex
+	providers map[string]AuthProvider
+}
+
+// NewRegistry returns an empty provider Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		providers: make(map[string]AuthProvider),
+	}
+}
+
+// Register adds provider under name, overwriting any existing registration.
+func (r *Registry) Register(name string, provider AuthProvider) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.providers[name] = provider
+}
+
+// Get returns the AuthProvider registered under name, if any.
+func (r *Registry) Get(name string) (AuthProvider, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	provider, ok := r.providers[name]
+	return provider, ok
+}
+
+// MustGet returns the AuthProvider registered under name, or panics if it
+// has not been registered. It exists for wiring call sites (e.g. route
+// registration) that can only fail at startup.
+func (r *Registry) MustGet(name string) AuthProvider {
+	provider, ok := r.Get(name)
+	if !ok {
+		panic(fmt.Sprintf("auth: no provider registered under %q", name))
+	}
+	return provider
+}
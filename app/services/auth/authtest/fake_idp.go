@@ -0,0 +1,167 @@
+// Package authtest provides a fake OAuth identity provider for tests that
+// need to exercise the redirect -> callback -> user-creation login flow
+// without any network access to the real provider.
+package authtest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+)
+
+// FailureMode selects a failure scenario FakeIdP should simulate.
+type FailureMode int
+
+const (
+	// FailureNone simulates a healthy provider.
+	FailureNone FailureMode = iota
+	// FailureExpiredToken makes /token issue an already-expired token.
+	FailureExpiredToken
+	// FailureMissingPrimaryEmail makes /user/emails report no email
+	// flagged as primary.
+	FailureMissingPrimaryEmail
+	// FailureOrgMembershipDenied makes /user/orgs report no
+	// organisations, so an org allow-list check fails closed.
+	FailureOrgMembershipDenied
+)
+
+// Claims are the user profile FakeIdP reports for any code/token it
+// issues.
+type Claims struct {
+	ID    int64
+	Login string
+	Email string
+}
+
+// FakeIdP is a minimal stand-in for Github's OAuth and REST endpoints.
+// Point an *oauth2.Config's Endpoint at AuthURL/TokenURL, and a
+// providers/github fetcher's base URL at APIBaseURL, to exercise the full
+// login flow against it.
+type FakeIdP struct {
+	Server  *httptest.Server
+	Claims  Claims
+	Failure FailureMode
+}
+
+// New starts a FakeIdP reporting claims. Callers must Close it.
+func New(claims Claims) *FakeIdP {
+	idp := &FakeIdP{Claims: claims}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/authorize", idp.handleAuthorize)
+	mux.HandleFunc("/token", idp.handleToken)
+	mux.HandleFunc("/user", idp.handleUser)
+	mux.HandleFunc("/user/emails", idp.handleUserEmails)
+	mux.HandleFunc("/user/orgs", idp.handleUserOrgs)
+	mux.HandleFunc("/user/teams", idp.handleUserTeams)
+	idp.Server = httptest.NewServer(mux)
+
+	return idp
+}
+
+// Close shuts down the underlying httptest.Server.
+func (idp *FakeIdP) Close() {
+	idp.Server.Close()
+}
+
+// AuthURL is the /authorize endpoint, for wiring into an oauth2.Endpoint.
+func (idp *FakeIdP) AuthURL() string {
+	return idp.Server.URL + "/authorize"
+}
+
+// TokenURL is the /token endpoint, for wiring into an oauth2.Endpoint.
+func (idp *FakeIdP) TokenURL() string {
+	return idp.Server.URL + "/token"
+}
+
+// APIBaseURL is the base URL the profile/org endpoints are served under,
+// for providers/github.NewUserInfoFetcherWithBaseURL.
+func (idp *FakeIdP) APIBaseURL() string {
+	return idp.Server.URL + "/"
+}
+
+// handleAuthorize mimics the provider's consent screen by immediately
+// redirecting back to redirect_uri with a fixed code and the caller's
+// state, as if the user had approved access.
+func (idp *FakeIdP) handleAuthorize(w http.ResponseWriter, r *http.Request) {
+	callback, err := url.Parse(r.URL.Query().Get("redirect_uri"))
+	if err != nil {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+	q := callback.Query()
+	q.Set("code", "fake-code")
+	q.Set("state", r.URL.Query().Get("state"))
+	callback.RawQuery = q.Encode()
+	http.Redirect(w, r, callback.String(), http.StatusFound)
+}
+
+func (idp *FakeIdP) handleToken(w http.ResponseWriter, r *http.Request) {
+	expiresIn := 3600
+	if idp.Failure == FailureExpiredToken {
+		expiresIn = -3600
+	}
+	writeJSON(w, map[string]interface{}{
+		"access_token":  "fake-access-token",
+		"refresh_token": "fake-refresh-token",
+		"token_type":    "bearer",
+		"expires_in":    expiresIn,
+	})
+}
+
+func (idp *FakeIdP) handleUser(w http.ResponseWriter, r *http.Request) {
+	if idp.rejectExpiredToken(w, r) {
+		return
+	}
+	writeJSON(w, map[string]interface{}{
+		"id":    idp.Claims.ID,
+		"login": idp.Claims.Login,
+	})
+}
+
+func (idp *FakeIdP) handleUserEmails(w http.ResponseWriter, r *http.Request) {
+	if idp.rejectExpiredToken(w, r) {
+		return
+	}
+	primary := idp.Failure != FailureMissingPrimaryEmail
+	writeJSON(w, []map[string]interface{}{
+		{"email": idp.Claims.Email, "primary": primary, "verified": true},
+	})
+}
+
+func (idp *FakeIdP) handleUserOrgs(w http.ResponseWriter, r *http.Request) {
+	if idp.rejectExpiredToken(w, r) {
+		return
+	}
+	if idp.Failure == FailureOrgMembershipDenied {
+		writeJSON(w, []map[string]interface{}{})
+		return
+	}
+	writeJSON(w, []map[string]interface{}{
+		{"login": "acme-corp"},
+	})
+}
+
+func (idp *FakeIdP) handleUserTeams(w http.ResponseWriter, r *http.Request) {
+	if idp.rejectExpiredToken(w, r) {
+		return
+	}
+	writeJSON(w, []map[string]interface{}{})
+}
+
+// rejectExpiredToken simulates a resource server that rejects an expired
+// access token outright rather than silently honouring it, writing a 401
+// and returning true if it did so.
+func (idp *FakeIdP) rejectExpiredToken(w http.ResponseWriter, r *http.Request) bool {
+	if idp.Failure != FailureExpiredToken {
+		return false
+	}
+	http.Error(w, `{"message":"Bad credentials"}`, http.StatusUnauthorized)
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
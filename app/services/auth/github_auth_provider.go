@@ -5,98 +5,361 @@ import (
 	"ai-developer/app/models"
 	"ai-developer/app/services"
 	"context"
+	"crypto/rand"
+	"encoding/base64"
 	"errors"
 	"github.com/gin-gonic/gin"
 	"github.com/google/go-github/github"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 	"golang.org/x/oauth2"
 	oauthGithub "golang.org/x/oauth2/github"
 	"gorm.io/gorm"
 	"net/http"
+	"net/url"
+	"strings"
+	"time"
 )
 
+// oauthSessionCookie names the cookie used to correlate a BeginLogin
+// request with its matching HandleCallback request, so the CSRF state can
+// be looked up in the StateStore regardless of which replica serves the
+// callback.
+const oauthSessionCookie = "ss_oauth_session"
+
+// oauthStateTTL bounds how long a login can stay pending before the state
+// is considered expired and the callback is rejected.
+const oauthStateTTL = 10 * time.Minute
+
 type GithubAuthProvider struct {
-	AuthProvider
 	logger              *zap.Logger
 	userService         *services.UserService
 	organisationService *services.OrganisationService
 	githubOAuthConfig   *config.GithubOAuthConfig
+	stateStore          StateStore
+	tokenEncryptor      TokenEncryptor
+	tokenService        *services.TokenService
+	tokenExchanger      TokenExchanger
+	userInfoFetcher     UserInfoFetcher
 }
 
-func (gap GithubAuthProvider) Authenticate(c *gin.Context) (user interface{}, err error) {
-	gap.logger.Debug("Authenticating user with Github")
-
-	c.Redirect(http.StatusFound, gap.githubOAuthConfig.FrontendURL())
-
-	code := c.Query("code")
-	githubOauthConfig := &oauth2.Config{
+func (gap GithubAuthProvider) oauthConfig() *oauth2.Config {
+	return &oauth2.Config{
 		ClientID:     gap.githubOAuthConfig.ClientId(),
 		ClientSecret: gap.githubOAuthConfig.ClientSecret(),
 		RedirectURL:  gap.githubOAuthConfig.RedirectURL(),
-		Scopes:       []string{"user:email"},
+		Scopes:       []string{"user:email", "read:org"},
 		Endpoint:     oauthGithub.Endpoint,
 	}
+}
+
+// BeginLogin generates a random CSRF state, stores it keyed by the
+// caller's oauth session cookie (minting one if absent), and redirects the
+// browser to Github's consent screen.
+func (gap GithubAuthProvider) BeginLogin(c *gin.Context) error {
+	gap.logger.Debug("Beginning Github login")
+
+	sessionID, err := c.Cookie(oauthSessionCookie)
+	if err != nil || sessionID == "" {
+		sessionID = uuid.NewString()
+		c.SetCookie(oauthSessionCookie, sessionID, int(oauthStateTTL.Seconds()), "/", "", gap.tokenService.SecureCookies(), true)
+	}
+
+	state, err := generateState()
+	if err != nil {
+		gap.logger.Error("Error generating oauth state", zap.Error(err))
+		return err
+	}
 
-	token, err := githubOauthConfig.Exchange(context.Background(), code)
+	if err = gap.stateStore.Save(c.Request.Context(), sessionID, state, oauthStateTTL); err != nil {
+		gap.logger.Error("Error persisting oauth state", zap.Error(err))
+		return err
+	}
+
+	c.Redirect(http.StatusFound, gap.oauthConfig().AuthCodeURL(state))
+	return nil
+}
+
+// HandleCallback validates the CSRF state returned by Github, exchanges
+// the code for a token, and resolves (or creates) the authenticated user.
+func (gap GithubAuthProvider) HandleCallback(c *gin.Context) (user interface{}, err error) {
+	gap.logger.Debug("Handling Github callback")
+
+	sessionID, err := c.Cookie(oauthSessionCookie)
+	if err != nil || sessionID == "" {
+		return nil, errors.New("auth: missing oauth session cookie")
+	}
+
+	state := c.Query("state")
+	valid, err := gap.stateStore.Verify(c.Request.Context(), sessionID, state)
+	if err != nil {
+		gap.logger.Error("Error verifying oauth state", zap.Error(err))
+		return nil, err
+	}
+	if !valid {
+		gap.logger.Warn("Rejecting Github callback with invalid oauth state")
+		return nil, errors.New("auth: invalid oauth state")
+	}
+
+	code := c.Query("code")
+
+	token, err := gap.tokenExchanger.Exchange(context.Background(), code)
 	if err != nil {
 		gap.logger.Error("Error exchanging code for token", zap.Error(err))
 		return
 	}
 
-	client := github.NewClient(githubOauthConfig.Client(context.Background(), token))
+	matchedOrg, err := gap.authorizeMembership(context.Background(), token)
+	if err != nil {
+		gap.logger.Warn("Rejecting Github login, membership check failed", zap.Error(err))
+		return
+	}
+
+	githubUser, err := gap.userInfoFetcher.GetUser(context.Background(), token)
+	if err != nil {
+		gap.logger.Error("Error fetching user from Github", zap.Error(err))
+		return
+	}
+	providerUserID := githubUser.ID
 
-	emails, _, err := client.Users.ListEmails(context.Background(), nil)
+	primaryEmail, err := gap.userInfoFetcher.PrimaryEmail(context.Background(), token)
 	if err != nil {
 		gap.logger.Error("Error fetching user emails", zap.Error(err))
 		return
 	}
 
-	var primaryEmail string
-	for _, email := range emails {
-		if email.GetPrimary() {
-			primaryEmail = email.GetEmail()
-			break
+	// Github's numeric user ID is stable across email/login changes, so a
+	// previously-linked identity takes priority over an email lookup.
+	identity, err := gap.userService.GetIdentity(providerGithub, providerUserID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		gap.logger.Error("Error fetching identity", zap.Error(err))
+		return
+	}
+
+	if identity != nil {
+		existingUser, userErr := gap.userService.GetUserById(identity.UserID)
+		if userErr != nil {
+			gap.logger.Error("Error fetching user for identity", zap.Error(userErr))
+			return nil, userErr
+		}
+		gap.logger.Debug("User authenticated with Github via linked identity", zap.Any("user", existingUser))
+		if err = gap.linkIdentity(existingUser.ID, providerUserID, token); err != nil {
+			gap.logger.Error("Error refreshing stored identity tokens", zap.Error(err))
+			return nil, err
 		}
+		if err = issueSessionCookie(c, gap.tokenService, existingUser.ID, existingUser.OrganisationID); err != nil {
+			gap.logger.Error("Error issuing session cookie", zap.Error(err))
+			return nil, err
+		}
+		return existingUser, nil
 	}
 
 	existingUser, err := gap.userService.GetUserByEmail(primaryEmail)
-
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
 		gap.logger.Error("Error fetching user by email", zap.Error(err))
 		return
 	}
 
+	// No identity row yet: fall back to linking this Github account onto
+	// the existing user found by email.
 	if existingUser != nil {
-		gap.logger.Debug("User authenticated with Github", zap.Any("user", existingUser))
+		gap.logger.Debug("User authenticated with Github, linking identity by email fallback", zap.Any("user", existingUser))
+		if err = gap.linkIdentity(existingUser.ID, providerUserID, token); err != nil {
+			gap.logger.Error("Error linking Github identity", zap.Error(err))
+			return nil, err
+		}
+		if err = issueSessionCookie(c, gap.tokenService, existingUser.ID, existingUser.OrganisationID); err != nil {
+			gap.logger.Error("Error issuing session cookie", zap.Error(err))
+			return nil, err
+		}
 		return existingUser, nil
 	}
 
 	gap.logger.Debug("User not found, creating new user")
 	err = nil
-	var githubUser *github.User
-	githubUser, _, err = client.Users.Get(context.Background(), "")
+	newUser, err := gap.CreateUser(primaryEmail, githubUser, matchedOrg)
 	if err != nil {
-		gap.logger.Error("Error fetching user from Github", zap.Error(err))
 		return
 	}
-	return gap.CreateUser(primaryEmail, githubUser)
+	if err = gap.linkIdentity(newUser.ID, providerUserID, token); err != nil {
+		gap.logger.Error("Error linking Github identity", zap.Error(err))
+		return nil, err
+	}
+	if err = issueSessionCookie(c, gap.tokenService, newUser.ID, newUser.OrganisationID); err != nil {
+		gap.logger.Error("Error issuing session cookie", zap.Error(err))
+		return nil, err
+	}
+	return newUser, nil
 }
 
-func (gap GithubAuthProvider) CreateUser(email string, githubUser *github.User) (user *models.User, err error) {
-	var name string
-	if githubUser.Login != nil {
-		name = *githubUser.Login
-	} else {
-		name = "N/A"
-	}
+// providerGithub identifies Github in the user_identities table.
+const providerGithub = "github"
 
-	organisation := &models.Organisation{
-		Name: gap.organisationService.CreateOrganisationName(),
+// linkIdentity encrypts token's access/refresh tokens and persists (or
+// updates) the user_identities row for userID.
+func (gap GithubAuthProvider) linkIdentity(userID uint, providerUserID string, token *oauth2.Token) error {
+	encryptedAccessToken, encryptedRefreshToken, err := gap.encryptToken(token)
+	if err != nil {
+		return err
+	}
+	var expiresAt *time.Time
+	if !token.Expiry.IsZero() {
+		expiresAt = &token.Expiry
 	}
-	_, err = gap.organisationService.CreateOrganisation(organisation)
+	return gap.userService.LinkIdentity(userID, providerGithub, providerUserID, encryptedAccessToken, encryptedRefreshToken, expiresAt)
+}
+
+func (gap GithubAuthProvider) encryptToken(token *oauth2.Token) (accessToken string, refreshToken string, err error) {
+	accessToken, err = gap.tokenEncryptor.Encrypt(token.AccessToken)
 	if err != nil {
-		gap.logger.Error("Error creating organisation", zap.Error(err))
-		return
+		return "", "", err
+	}
+	if token.RefreshToken != "" {
+		refreshToken, err = gap.tokenEncryptor.Encrypt(token.RefreshToken)
+		if err != nil {
+			return "", "", err
+		}
+	}
+	return accessToken, refreshToken, nil
+}
+
+// ErrOrganisationNotAllowed is returned when the configured org/team
+// allow-lists are non-empty and the authenticated Github user does not
+// belong to any of them.
+var ErrOrganisationNotAllowed = errors.New("auth: user is not a member of an allowed Github organisation or team")
+
+// authorizeMembership checks the authenticated Github user's organisation
+// and team membership against the configured allow-lists. If both
+// allow-lists are empty, membership is not checked and ("", nil) is
+// returned. Otherwise it returns the login of the first allowed
+// organisation the user belongs to or, failing that, the organisation
+// login of an allowed team the user belongs to (which need not itself be
+// in the organisation allow-list), so the caller can map the login into
+// an existing SuperCoder organisation.
+//
+// Unlike the rest of the callback flow, this builds its own Github API
+// client rather than going through the injected UserInfoFetcher, since
+// org/team membership isn't part of that interface. Tests that don't
+// configure an allow-list never reach this code path; tests that do can
+// still avoid the network by pointing config.GithubOAuthConfig.APIBaseURL
+// at a fake IdP.
+func (gap GithubAuthProvider) authorizeMembership(ctx context.Context, token *oauth2.Token) (string, error) {
+	allowedOrgs := gap.githubOAuthConfig.AllowedOrganisations()
+	allowedTeams := gap.githubOAuthConfig.AllowedTeams()
+	if len(allowedOrgs) == 0 && len(allowedTeams) == 0 {
+		return "", nil
+	}
+
+	client := github.NewClient(gap.oauthConfig().Client(ctx, token))
+	if baseURL := gap.githubOAuthConfig.APIBaseURL(); baseURL != "" {
+		parsed, err := url.Parse(baseURL)
+		if err != nil {
+			return "", err
+		}
+		client.BaseURL = parsed
+	}
+
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		orgs, resp, err := client.Organizations.List(ctx, "", opts)
+		if err != nil {
+			return "", err
+		}
+		for _, org := range orgs {
+			if stringSliceContains(allowedOrgs, org.GetLogin()) {
+				return org.GetLogin(), nil
+			}
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	if len(allowedTeams) > 0 {
+		teamOpts := &github.ListOptions{PerPage: 100}
+		for {
+			teams, resp, err := client.Teams.ListUserTeams(ctx, teamOpts)
+			if err != nil {
+				return "", err
+			}
+			for _, spec := range allowedTeams {
+				orgLogin, teamSlug, ok := splitTeamSpec(spec)
+				if !ok {
+					continue
+				}
+				for _, team := range teams {
+					if team.GetSlug() == teamSlug && team.GetOrganization().GetLogin() == orgLogin {
+						return orgLogin, nil
+					}
+				}
+			}
+			if resp.NextPage == 0 {
+				break
+			}
+			teamOpts.Page = resp.NextPage
+		}
+	}
+
+	return "", ErrOrganisationNotAllowed
+}
+
+func stringSliceContains(haystack []string, needle string) bool {
+	for _, candidate := range haystack {
+		if candidate == needle {
+			return true
+		}
+	}
+	return false
+}
+
+// splitTeamSpec parses an "org/team-slug" allow-list entry.
+func splitTeamSpec(spec string) (orgLogin string, teamSlug string, ok bool) {
+	idx := strings.Index(spec, "/")
+	if idx < 0 {
+		return "", "", false
+	}
+	return spec[:idx], spec[idx+1:], true
+}
+
+// generateState returns a cryptographically random, URL-safe OAuth state
+// value.
+func generateState() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// CreateUser provisions a new SuperCoder user for an authenticated Github
+// account. When githubOrgLogin is non-empty (the user matched one of the
+// configured allow-listed organisations), the user is attached to the
+// shared SuperCoder organisation mapped to that Github org instead of a
+// freshly minted one, so every member of the same Github org lands in the
+// same workspace.
+func (gap GithubAuthProvider) CreateUser(email string, githubUser *ExternalUser, githubOrgLogin string) (user *models.User, err error) {
+	name := githubUser.Login
+	if name == "" {
+		name = "N/A"
+	}
+
+	var organisation *models.Organisation
+	if githubOrgLogin != "" {
+		organisation, err = gap.organisationService.GetOrCreateOrganisationByGithubOrg(githubOrgLogin)
+		if err != nil {
+			gap.logger.Error("Error resolving organisation for Github org", zap.Error(err))
+			return
+		}
+	} else {
+		organisation = &models.Organisation{
+			Name: gap.organisationService.CreateOrganisationName(),
+		}
+		_, err = gap.organisationService.CreateOrganisation(organisation)
+		if err != nil {
+			gap.logger.Error("Error creating organisation", zap.Error(err))
+			return
+		}
 	}
 
 	hashedPassword, err := gap.userService.HashUserPassword(gap.userService.CreatePassword())
@@ -124,12 +387,22 @@ func NewGithubAuthProvider(
 	githubOAuthConfig *config.GithubOAuthConfig,
 	userService *services.UserService,
 	organisationService *services.OrganisationService,
+	stateStore StateStore,
+	tokenEncryptor TokenEncryptor,
+	tokenService *services.TokenService,
+	tokenExchanger TokenExchanger,
+	userInfoFetcher UserInfoFetcher,
 	logger *zap.Logger,
 ) *GithubAuthProvider {
 	return &GithubAuthProvider{
 		userService:         userService,
 		organisationService: organisationService,
 		githubOAuthConfig:   githubOAuthConfig,
+		stateStore:          stateStore,
+		tokenEncryptor:      tokenEncryptor,
+		tokenService:        tokenService,
+		tokenExchanger:      tokenExchanger,
+		userInfoFetcher:     userInfoFetcher,
 		logger:              logger.Named("GithubAuthProvider"),
 	}
 }
\ No newline at end of file
@@ -0,0 +1,94 @@
+package auth
+
+import (
+	"ai-developer/app/config"
+	"ai-developer/app/services/auth/authtest"
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"golang.org/x/oauth2"
+)
+
+// newTestGithubOAuthConfig builds a GithubOAuthConfig pointed at idp, with
+// the given org/team allow-lists, via the same environment variables
+// NewGithubOAuthConfig reads in production.
+func newTestGithubOAuthConfig(t *testing.T, idp *authtest.FakeIdP, allowedOrgs []string, allowedTeams []string) *config.GithubOAuthConfig {
+	t.Helper()
+	env := map[string]string{
+		"GITHUB_OAUTH_CLIENT_ID":     "test-client-id",
+		"GITHUB_OAUTH_CLIENT_SECRET": "test-client-secret",
+		"GITHUB_OAUTH_REDIRECT_URL":  "https://supercoder.example/oauth/github/callback",
+		"GITHUB_OAUTH_ALLOWED_ORGS":  strings.Join(allowedOrgs, ","),
+		"GITHUB_OAUTH_ALLOWED_TEAMS": strings.Join(allowedTeams, ","),
+		"GITHUB_API_BASE_URL":        idp.APIBaseURL(),
+	}
+	for key, value := range env {
+		_ = os.Setenv(key, value)
+	}
+	t.Cleanup(func() {
+		for key := range env {
+			_ = os.Unsetenv(key)
+		}
+	})
+	return config.NewGithubOAuthConfig()
+}
+
+func fakeAccessToken() *oauth2.Token {
+	return &oauth2.Token{AccessToken: "fake-access-token"}
+}
+
+func TestAuthorizeMembership_NoAllowListConfigured(t *testing.T) {
+	idp := authtest.New(authtest.Claims{})
+	defer idp.Close()
+
+	gap := GithubAuthProvider{
+		logger:            zap.NewNop(),
+		githubOAuthConfig: newTestGithubOAuthConfig(t, idp, nil, nil),
+	}
+
+	org, err := gap.authorizeMembership(context.Background(), fakeAccessToken())
+	if err != nil {
+		t.Fatalf("authorizeMembership() error = %v", err)
+	}
+	if org != "" {
+		t.Fatalf("authorizeMembership() org = %q, want empty", org)
+	}
+}
+
+func TestAuthorizeMembership_AllowedOrgMatches(t *testing.T) {
+	idp := authtest.New(authtest.Claims{ID: 42, Login: "octocat"})
+	defer idp.Close()
+
+	gap := GithubAuthProvider{
+		logger:            zap.NewNop(),
+		githubOAuthConfig: newTestGithubOAuthConfig(t, idp, []string{"acme-corp"}, nil),
+	}
+
+	org, err := gap.authorizeMembership(context.Background(), fakeAccessToken())
+	if err != nil {
+		t.Fatalf("authorizeMembership() error = %v", err)
+	}
+	if org != "acme-corp" {
+		t.Fatalf("authorizeMembership() org = %q, want acme-corp", org)
+	}
+}
+
+func TestAuthorizeMembership_Denied(t *testing.T) {
+	idp := authtest.New(authtest.Claims{ID: 42, Login: "octocat"})
+	idp.Failure = authtest.FailureOrgMembershipDenied
+	defer idp.Close()
+
+	gap := GithubAuthProvider{
+		logger:            zap.NewNop(),
+		githubOAuthConfig: newTestGithubOAuthConfig(t, idp, []string{"acme-corp"}, nil),
+	}
+
+	_, err := gap.authorizeMembership(context.Background(), fakeAccessToken())
+	if !errors.Is(err, ErrOrganisationNotAllowed) {
+		t.Fatalf("authorizeMembership() error = %v, want ErrOrganisationNotAllowed", err)
+	}
+}
@@ -0,0 +1,43 @@
+package auth
+
+import (
+	"ai-developer/app/middlewares"
+	"ai-developer/app/models"
+	"ai-developer/app/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// unlinkIdentityRequest is the body UnlinkIdentityHandler expects.
+type unlinkIdentityRequest struct {
+	Provider       string `json:"provider" binding:"required"`
+	ProviderUserID string `json:"provider_user_id" binding:"required"`
+}
+
+// UnlinkIdentityHandler returns a gin.HandlerFunc that detaches the
+// caller's linked external identity for the given provider. It must run
+// behind middlewares.AuthRequired, which populates
+// middlewares.ContextKeyUser.
+func UnlinkIdentityHandler(userService *services.UserService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := c.MustGet(middlewares.ContextKeyUser).(*models.User)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing session"})
+			return
+		}
+
+		var req unlinkIdentityRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if err := userService.UnlinkIdentity(user.ID, req.Provider, req.ProviderUserID); err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "failed to unlink identity"})
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
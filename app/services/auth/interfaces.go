@@ -0,0 +1,38 @@
+package auth
+
+import (
+	"context"
+
+	"golang.org/x/oauth2"
+)
+
+// ExternalUser is the subset of a provider's user profile an AuthProvider
+// needs to create or match a SuperCoder user. It deliberately excludes
+// any provider-specific type (e.g. *github.User) so the rest of the login
+// flow, and its tests, never depend on a concrete provider SDK.
+type ExternalUser struct {
+	// ID is the provider's own stable user identifier (e.g. Github's
+	// numeric user ID, stringified). It must not change across profile
+	// edits such as renaming the login or changing the primary email.
+	ID string
+	// Login is the provider's display/login name, used only to name a
+	// newly created SuperCoder user.
+	Login string
+}
+
+// TokenExchanger exchanges an OAuth authorization code for a token. It
+// exists so GithubAuthProvider (and future providers) can be unit-tested
+// against a fake IdP instead of the real provider.
+type TokenExchanger interface {
+	Exchange(ctx context.Context, code string) (*oauth2.Token, error)
+}
+
+// UserInfoFetcher loads profile information for the user behind an OAuth
+// token.
+type UserInfoFetcher interface {
+	// PrimaryEmail returns the verified primary email for the token's
+	// owner.
+	PrimaryEmail(ctx context.Context, token *oauth2.Token) (string, error)
+	// GetUser returns the token owner's external profile.
+	GetUser(ctx context.Context, token *oauth2.Token) (*ExternalUser, error)
+}
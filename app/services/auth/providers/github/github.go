@@ -0,0 +1,105 @@
+// Package github provides the real Github-backed implementations of
+// auth.TokenExchanger and auth.UserInfoFetcher. It is kept separate from
+// package auth so the core login flow reaches the token exchange and the
+// profile lookup only through those interfaces, and can be unit-tested
+// against a fake IdP. Package auth still uses the Github API client
+// directly for its org/team membership check.
+package github
+
+import (
+	"ai-developer/app/services/auth"
+	"context"
+	"errors"
+	"net/url"
+	"strconv"
+
+	"github.com/google/go-github/github"
+	"golang.org/x/oauth2"
+)
+
+// ErrNoPrimaryEmail is returned by PrimaryEmail when the Github account
+// behind the token has no email that is both flagged as primary and
+// verified.
+var ErrNoPrimaryEmail = errors.New("github: account has no verified primary email")
+
+// TokenExchanger exchanges an OAuth code for a token via oauthConfig's
+// token endpoint.
+type TokenExchanger struct {
+	oauthConfig *oauth2.Config
+}
+
+// NewTokenExchanger builds a TokenExchanger that exchanges codes using
+// oauthConfig.
+func NewTokenExchanger(oauthConfig *oauth2.Config) *TokenExchanger {
+	return &TokenExchanger{oauthConfig: oauthConfig}
+}
+
+func (e *TokenExchanger) Exchange(ctx context.Context, code string) (*oauth2.Token, error) {
+	return e.oauthConfig.Exchange(ctx, code)
+}
+
+// UserInfoFetcher loads profile information from the Github API.
+type UserInfoFetcher struct {
+	oauthConfig *oauth2.Config
+	// apiBaseURL overrides the default https://api.github.com/ base, so
+	// tests can point this at a fake IdP's REST endpoints. Empty means
+	// use the go-github default.
+	apiBaseURL string
+}
+
+// NewUserInfoFetcher builds a UserInfoFetcher that authenticates its
+// requests using oauthConfig against the real Github API.
+func NewUserInfoFetcher(oauthConfig *oauth2.Config) *UserInfoFetcher {
+	return &UserInfoFetcher{oauthConfig: oauthConfig}
+}
+
+// NewUserInfoFetcherWithBaseURL is NewUserInfoFetcher but against
+// apiBaseURL instead of the real Github API, for use in tests against a
+// fake IdP.
+func NewUserInfoFetcherWithBaseURL(oauthConfig *oauth2.Config, apiBaseURL string) *UserInfoFetcher {
+	return &UserInfoFetcher{oauthConfig: oauthConfig, apiBaseURL: apiBaseURL}
+}
+
+func (f *UserInfoFetcher) client(ctx context.Context, token *oauth2.Token) (*github.Client, error) {
+	client := github.NewClient(f.oauthConfig.Client(ctx, token))
+	if f.apiBaseURL != "" {
+		baseURL, err := url.Parse(f.apiBaseURL)
+		if err != nil {
+			return nil, err
+		}
+		client.BaseURL = baseURL
+	}
+	return client, nil
+}
+
+func (f *UserInfoFetcher) PrimaryEmail(ctx context.Context, token *oauth2.Token) (string, error) {
+	client, err := f.client(ctx, token)
+	if err != nil {
+		return "", err
+	}
+	emails, _, err := client.Users.ListEmails(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+	for _, email := range emails {
+		if email.GetPrimary() && email.GetVerified() {
+			return email.GetEmail(), nil
+		}
+	}
+	return "", ErrNoPrimaryEmail
+}
+
+func (f *UserInfoFetcher) GetUser(ctx context.Context, token *oauth2.Token) (*auth.ExternalUser, error) {
+	client, err := f.client(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	githubUser, _, err := client.Users.Get(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	return &auth.ExternalUser{
+		ID:    strconv.FormatInt(githubUser.GetID(), 10),
+		Login: githubUser.GetLogin(),
+	}, nil
+}
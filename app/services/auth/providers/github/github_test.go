@@ -0,0 +1,87 @@
+package github
+
+import (
+	"ai-developer/app/services/auth/authtest"
+	"context"
+	"errors"
+	"testing"
+
+	"golang.org/x/oauth2"
+)
+
+func newOAuthConfig(idp *authtest.FakeIdP) *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     "test-client-id",
+		ClientSecret: "test-client-secret",
+		RedirectURL:  "https://supercoder.example/oauth/github/callback",
+		Scopes:       []string{"user:email", "read:org"},
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  idp.AuthURL(),
+			TokenURL: idp.TokenURL(),
+		},
+	}
+}
+
+func TestUserInfoFetcher_HappyPath(t *testing.T) {
+	idp := authtest.New(authtest.Claims{ID: 42, Login: "octocat", Email: "octocat@example.com"})
+	defer idp.Close()
+
+	oauthConfig := newOAuthConfig(idp)
+	token, err := NewTokenExchanger(oauthConfig).Exchange(context.Background(), "fake-code")
+	if err != nil {
+		t.Fatalf("Exchange() error = %v", err)
+	}
+
+	fetcher := NewUserInfoFetcherWithBaseURL(oauthConfig, idp.APIBaseURL())
+
+	user, err := fetcher.GetUser(context.Background(), token)
+	if err != nil {
+		t.Fatalf("GetUser() error = %v", err)
+	}
+	if user.ID != "42" || user.Login != "octocat" {
+		t.Fatalf("GetUser() = %+v, want ID=42 Login=octocat", user)
+	}
+
+	email, err := fetcher.PrimaryEmail(context.Background(), token)
+	if err != nil {
+		t.Fatalf("PrimaryEmail() error = %v", err)
+	}
+	if email != "octocat@example.com" {
+		t.Fatalf("PrimaryEmail() = %q, want octocat@example.com", email)
+	}
+}
+
+func TestUserInfoFetcher_MissingPrimaryEmail(t *testing.T) {
+	idp := authtest.New(authtest.Claims{ID: 42, Login: "octocat", Email: "octocat@example.com"})
+	idp.Failure = authtest.FailureMissingPrimaryEmail
+	defer idp.Close()
+
+	oauthConfig := newOAuthConfig(idp)
+	token, err := NewTokenExchanger(oauthConfig).Exchange(context.Background(), "fake-code")
+	if err != nil {
+		t.Fatalf("Exchange() error = %v", err)
+	}
+
+	fetcher := NewUserInfoFetcherWithBaseURL(oauthConfig, idp.APIBaseURL())
+	_, err = fetcher.PrimaryEmail(context.Background(), token)
+	if !errors.Is(err, ErrNoPrimaryEmail) {
+		t.Fatalf("PrimaryEmail() error = %v, want ErrNoPrimaryEmail", err)
+	}
+}
+
+func TestUserInfoFetcher_ExpiredToken(t *testing.T) {
+	idp := authtest.New(authtest.Claims{ID: 42, Login: "octocat", Email: "octocat@example.com"})
+	idp.Failure = authtest.FailureExpiredToken
+	defer idp.Close()
+
+	oauthConfig := newOAuthConfig(idp)
+	token, err := NewTokenExchanger(oauthConfig).Exchange(context.Background(), "fake-code")
+	if err != nil {
+		t.Fatalf("Exchange() error = %v", err)
+	}
+
+	fetcher := NewUserInfoFetcherWithBaseURL(oauthConfig, idp.APIBaseURL())
+	if _, err := fetcher.GetUser(context.Background(), token); err == nil {
+		t.Fatal("GetUser() error = nil, want an error for an expired token")
+	}
+}
@@ -0,0 +1,74 @@
+package auth
+
+import (
+	"ai-developer/app/services"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// SessionCookieName is the HttpOnly cookie browser clients receive after a
+// successful login. The AuthRequired middleware reads the same cookie
+// through its own copy of this name, since it cannot import this package.
+const SessionCookieName = "ss_session"
+
+// issueSessionCookie mints a session JWT for userID/organisationID via
+// tokenService and sets it as an HttpOnly, SameSite=Lax cookie on c. The
+// cookie's Secure flag follows tokenService.SecureCookies, so it is only
+// ever disabled for local development.
+func issueSessionCookie(c *gin.Context, tokenService *services.TokenService, userID uint, organisationID uint) error {
+	tokenString, expiresAt, err := tokenService.IssueSession(userID, organisationID)
+	if err != nil {
+		return err
+	}
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(SessionCookieName, tokenString, int(time.Until(expiresAt).Seconds()), "/", "", tokenService.SecureCookies(), true)
+	return nil
+}
+
+// RefreshHandler returns a gin.HandlerFunc that rotates the caller's
+// session cookie, issuing a fresh JWT as long as the existing session has
+// not been revoked and is still within its refresh window.
+func RefreshHandler(tokenService *services.TokenService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenString, err := c.Cookie(SessionCookieName)
+		if err != nil || tokenString == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing session"})
+			return
+		}
+
+		newTokenString, expiresAt, err := tokenService.Refresh(tokenString)
+		if err != nil {
+			if errors.Is(err, services.ErrSessionRevoked) {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "session expired"})
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid session"})
+			return
+		}
+
+		c.SetSameSite(http.SameSiteLaxMode)
+		c.SetCookie(SessionCookieName, newTokenString, int(time.Until(expiresAt).Seconds()), "/", "", tokenService.SecureCookies(), true)
+		c.JSON(http.StatusOK, gin.H{"expires_at": expiresAt})
+	}
+}
+
+// LogoutHandler returns a gin.HandlerFunc that revokes the caller's
+// session server-side and clears the session cookie.
+func LogoutHandler(tokenService *services.TokenService, logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenString, err := c.Cookie(SessionCookieName)
+		if err == nil && tokenString != "" {
+			if claims, parseErr := tokenService.Parse(tokenString); parseErr == nil {
+				if revokeErr := tokenService.Revoke(claims.ID); revokeErr != nil {
+					logger.Error("Error revoking session", zap.Error(revokeErr))
+				}
+			}
+		}
+		c.SetCookie(SessionCookieName, "", -1, "/", "", tokenService.SecureCookies(), true)
+		c.Status(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,71 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrStateNotFound is returned by StateStore.Verify when key has no
+// associated state, either because it was never set, it already expired,
+// or it was already consumed.
+var ErrStateNotFound = errors.New("auth: oauth state not found or expired")
+
+// StateStore persists short-lived OAuth `state` values keyed by a
+// per-login session identifier. Implementations must be safe for
+// concurrent use since replicas/goroutines may service BeginLogin and
+// HandleCallback independently.
+type StateStore interface {
+	// Save stores state under key for ttl.
+	Save(ctx context.Context, key string, state string, ttl time.Duration) error
+	// Verify reports whether state matches the value stored under key. The
+	// stored value is consumed (deleted) regardless of outcome, so a state
+	// can only ever be redeemed once.
+	Verify(ctx context.Context, key string, state string) (bool, error)
+}
+
+type inMemoryStateEntry struct {
+	state     string
+	expiresAt time.Time
+}
+
+// InMemoryStateStore is a process-local StateStore. It is suitable for
+// single-replica deployments and for tests; multi-replica deployments
+// should use RedisStateStore instead so a callback handled by a different
+// replica than the one that started the login can still validate state.
+type InMemoryStateStore struct {
+	mu      sync.Mutex
+	entries map[string]inMemoryStateEntry
+}
+
+// NewInMemoryStateStore returns an empty InMemoryStateStore.
+func NewInMemoryStateStore() *InMemoryStateStore {
+	return &InMemoryStateStore{
+		entries: make(map[string]inMemoryStateEntry),
+	}
+}
+
+func (s *InMemoryStateStore) Save(_ context.Context, key string, state string, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[key] = inMemoryStateEntry{
+		state:     state,
+		expiresAt: time.Now().Add(ttl),
+	}
+	return nil
+}
+
+func (s *InMemoryStateStore) Verify(_ context.Context, key string, state string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.entries[key]
+	delete(s.entries, key)
+	if !ok {
+		return false, ErrStateNotFound
+	}
+	if time.Now().After(entry.expiresAt) {
+		return false, ErrStateNotFound
+	}
+	return entry.state == state, nil
+}
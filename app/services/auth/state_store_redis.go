@@ -0,0 +1,46 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisStateStore is a StateStore backed by Redis, so that the replica
+// handling a provider's callback does not need to be the same replica
+// that served BeginLogin.
+type RedisStateStore struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisStateStore returns a RedisStateStore using client. Keys are
+// namespaced under "oauth_state:" to avoid colliding with other uses of
+// the same Redis instance.
+func NewRedisStateStore(client *redis.Client) *RedisStateStore {
+	return &RedisStateStore{
+		client: client,
+		prefix: "oauth_state:",
+	}
+}
+
+func (s *RedisStateStore) Save(ctx context.Context, key string, state string, ttl time.Duration) error {
+	return s.client.Set(ctx, s.prefix+key, state, ttl).Err()
+}
+
+func (s *RedisStateStore) Verify(ctx context.Context, key string, state string) (bool, error) {
+	redisKey := s.prefix + key
+	stored, err := s.client.Get(ctx, redisKey).Result()
+	if errors.Is(err, redis.Nil) {
+		return false, ErrStateNotFound
+	}
+	if err != nil {
+		return false, err
+	}
+	// Best-effort single-use: delete regardless of match so a leaked state
+	// value cannot be replayed.
+	s.client.Del(ctx, redisKey)
+	return stored == state, nil
+}
@@ -0,0 +1,33 @@
+package services
+
+import (
+	"ai-developer/app/models"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// GetOrCreateOrganisationByGithubOrg returns the SuperCoder organisation
+// mapped to the given Github organisation login, creating one if this is
+// the first user authenticating from that Github org. This keeps every
+// member of the same Github org landing in a shared SuperCoder
+// organisation instead of each login minting its own.
+func (os *OrganisationService) GetOrCreateOrganisationByGithubOrg(githubOrgLogin string) (*models.Organisation, error) {
+	var organisation models.Organisation
+	err := os.db.Where("github_org = ?", githubOrgLogin).First(&organisation).Error
+	if err == nil {
+		return &organisation, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	organisation = models.Organisation{
+		Name:      githubOrgLogin,
+		GithubOrg: githubOrgLogin,
+	}
+	if err = os.db.Create(&organisation).Error; err != nil {
+		return nil, err
+	}
+	return &organisation, nil
+}
@@ -0,0 +1,165 @@
+package services
+
+import (
+	"ai-developer/app/config"
+	"ai-developer/app/models"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// SessionClaims are the custom JWT claims issued for an authenticated
+// SuperCoder session.
+type SessionClaims struct {
+	jwt.RegisteredClaims
+	OrganisationID uint `json:"org"`
+}
+
+// ErrSessionRevoked is returned by TokenService.Parse when the token's
+// jti is unknown or was explicitly revoked (logout), and by
+// TokenService.Refresh when the session is past its refresh window. An
+// expired token makes Parse return the JWT library's expiry error instead.
+var ErrSessionRevoked = errors.New("services: session has been revoked or expired")
+
+// TokenService mints, validates, and revokes the JWTs that back
+// authenticated SuperCoder sessions.
+type TokenService struct {
+	db        *gorm.DB
+	jwtConfig *config.JWTConfig
+	logger    *zap.Logger
+}
+
+// NewTokenService constructs a TokenService.
+func NewTokenService(db *gorm.DB, jwtConfig *config.JWTConfig, logger *zap.Logger) *TokenService {
+	return &TokenService{
+		db:        db,
+		jwtConfig: jwtConfig,
+		logger:    logger.Named("TokenService"),
+	}
+}
+
+// IssueSession mints a signed access token for userID/organisationID and
+// records its jti in the sessions table so it can later be revoked.
+func (ts *TokenService) IssueSession(userID uint, organisationID uint) (tokenString string, expiresAt time.Time, err error) {
+	jti, err := newJTI()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	now := time.Now()
+	expiresAt = now.Add(ts.jwtConfig.AccessTokenTTL())
+
+	claims := SessionClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   strconv.FormatUint(uint64(userID), 10),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			ID:        jti,
+		},
+		OrganisationID: organisationID,
+	}
+
+	token := jwt.NewWithClaims(ts.jwtConfig.SigningMethod(), claims)
+	tokenString, err = token.SignedString(ts.jwtConfig.SigningKey())
+	if err != nil {
+		ts.logger.Error("Error signing session token", zap.Error(err))
+		return "", time.Time{}, err
+	}
+
+	session := &models.Session{
+		UserID:    userID,
+		JTI:       jti,
+		ExpiresAt: expiresAt,
+	}
+	if err = ts.db.Create(session).Error; err != nil {
+		ts.logger.Error("Error persisting session", zap.Error(err))
+		return "", time.Time{}, err
+	}
+
+	return tokenString, expiresAt, nil
+}
+
+// Refresh validates tokenString (even if already expired, as long as the
+// session has not been revoked and is still within its refresh window)
+// and issues a replacement token for the same user/organisation, revoking
+// the old session.
+func (ts *TokenService) Refresh(tokenString string) (newTokenString string, expiresAt time.Time, err error) {
+	claims := &SessionClaims{}
+	_, err = jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		return ts.jwtConfig.VerificationKey(), nil
+	}, jwt.WithValidMethods([]string{ts.jwtConfig.SigningMethod().Alg()}), jwt.WithoutClaimsValidation())
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	var session models.Session
+	if err = ts.db.Where("jti = ? AND revoked_at IS NULL", claims.ID).First(&session).Error; err != nil {
+		return "", time.Time{}, err
+	}
+	if time.Since(session.CreatedAt) > ts.jwtConfig.RefreshTokenTTL() {
+		return "", time.Time{}, ErrSessionRevoked
+	}
+
+	if err = ts.Revoke(claims.ID); err != nil {
+		return "", time.Time{}, err
+	}
+
+	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return ts.IssueSession(uint(userID), claims.OrganisationID)
+}
+
+// Parse validates tokenString's signature and expiry, and checks that its
+// session has not been revoked.
+func (ts *TokenService) Parse(tokenString string) (*SessionClaims, error) {
+	claims := &SessionClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		return ts.jwtConfig.VerificationKey(), nil
+	}, jwt.WithValidMethods([]string{ts.jwtConfig.SigningMethod().Alg()}))
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, ErrSessionRevoked
+	}
+
+	var session models.Session
+	if err = ts.db.Where("jti = ? AND revoked_at IS NULL", claims.ID).First(&session).Error; err != nil {
+		return nil, ErrSessionRevoked
+	}
+
+	return claims, nil
+}
+
+// SecureCookies reports whether session cookies should be marked Secure,
+// per the underlying JWTConfig.
+func (ts *TokenService) SecureCookies() bool {
+	return ts.jwtConfig.SecureCookies()
+}
+
+// Revoke marks the session identified by jti as revoked, so Parse rejects
+// it even though the underlying JWT has not expired yet. This backs
+// logout.
+func (ts *TokenService) Revoke(jti string) error {
+	now := time.Now()
+	return ts.db.Model(&models.Session{}).
+		Where("jti = ?", jti).
+		Update("revoked_at", &now).Error
+}
+
+func newJTI() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,60 @@
+package services
+
+import (
+	"ai-developer/app/models"
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+// GetIdentity looks up the user_identities row linking a SuperCoder user
+// to a (provider, providerUserID) external account. Callers should treat
+// gorm.ErrRecordNotFound as "not linked yet" rather than a hard failure.
+func (us *UserService) GetIdentity(provider string, providerUserID string) (*models.UserIdentity, error) {
+	var identity models.UserIdentity
+	if err := us.db.Where("provider = ? AND provider_user_id = ?", provider, providerUserID).
+		First(&identity).Error; err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+// LinkIdentity attaches a (provider, providerUserID) external identity to
+// userID, storing its already-encrypted token ciphertexts. If the identity
+// is already linked, its tokens and owning user are updated in place, so
+// this also doubles as the "refresh stored tokens" path on repeat logins.
+func (us *UserService) LinkIdentity(
+	userID uint,
+	provider string,
+	providerUserID string,
+	accessTokenEncrypted string,
+	refreshTokenEncrypted string,
+	expiresAt *time.Time,
+) error {
+	identity := &models.UserIdentity{
+		UserID:                userID,
+		Provider:              provider,
+		ProviderUserID:        providerUserID,
+		AccessTokenEncrypted:  accessTokenEncrypted,
+		RefreshTokenEncrypted: refreshTokenEncrypted,
+		ExpiresAt:             expiresAt,
+	}
+	return us.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "provider"}, {Name: "provider_user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"user_id",
+			"access_token_encrypted",
+			"refresh_token_encrypted",
+			"expires_at",
+			"updated_at",
+		}),
+	}).Create(identity).Error
+}
+
+// UnlinkIdentity detaches the (provider, providerUserID) external identity
+// from userID, e.g. so a user can revoke a Github login without deleting
+// their SuperCoder account. It is a no-op if no such identity is linked.
+func (us *UserService) UnlinkIdentity(userID uint, provider string, providerUserID string) error {
+	return us.db.Where("user_id = ? AND provider = ? AND provider_user_id = ?", userID, provider, providerUserID).
+		Delete(&models.UserIdentity{}).Error
+}